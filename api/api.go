@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"github.com/bytedance/sonic/decoder"
 	"github.com/weplanx/fn/common"
@@ -54,27 +55,44 @@ type Event struct {
 	Reqid             int64  `json:"reqid"`
 }
 
+type Process func(x *API, ctx context.Context, dto Dto) error
+
+var processes = map[string]Process{
+	"tencent-cos-excel": (*API).TencentCosExcel,
+}
+
+func Register(name string, process Process) {
+	processes[name] = process
+}
+
 func (x *API) EventInvoke(w http.ResponseWriter, req *http.Request) {
 	if req.Method != "POST" {
+		w.Header().Set("Allow", "POST")
+		Fail(w, req, http.StatusMethodNotAllowed, fmt.Errorf(`method not allowed: %s`, req.Method))
 		return
 	}
 	ctx := req.Context()
+	name := req.PathValue("name")
+	if name == "" {
+		name = x.V.Process
+	}
+	process, ok := processes[name]
+	if !ok {
+		Fail(w, req, http.StatusNotFound, fmt.Errorf(`process not found: %s`, name))
+		return
+	}
 
 	var dto Dto
 	if err := decoder.
 		NewStreamDecoder(req.Body).
 		Decode(&dto); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		Fail(w, req, readStatus(err), err)
 		return
 	}
 
-	switch x.V.Process {
-	case "tencent-cos-excel":
-		if err := x.TencentCosExcel(ctx, dto); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		break
+	if err := process(x, ctx, dto); err != nil {
+		Fail(w, req, http.StatusInternalServerError, err)
+		return
 	}
 
 	w.WriteHeader(http.StatusOK)
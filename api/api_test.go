@@ -0,0 +1,61 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/weplanx/fn/common"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAPI_EventInvokeRegistry(t *testing.T) {
+	var invoked []string
+	stub := func(name string) Process {
+		return func(x *API, ctx context.Context, dto Dto) error {
+			invoked = append(invoked, name+":"+dto.Records[0].CosObject.Key)
+			if name == "failing" {
+				return errors.New("process failed")
+			}
+			return nil
+		}
+	}
+	Register("stub", stub("stub"))
+	Register("fallback", stub("fallback"))
+	Register("failing", stub("failing"))
+	t.Cleanup(func() {
+		delete(processes, "stub")
+		delete(processes, "fallback")
+		delete(processes, "failing")
+	})
+
+	v := new(common.Values)
+	v.Process = "fallback"
+	x := &API{Inject: &common.Inject{V: v}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/event-invoke", x.EventInvoke)
+	mux.HandleFunc("/event-invoke/{name}", x.EventInvoke)
+	body := `{"records":[{"cos":{"cosObject":{"key":"a.xlsx"}}}]}`
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/event-invoke/stub", strings.NewReader(body)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/event-invoke", strings.NewReader(body)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/event-invoke/unknown", strings.NewReader(body)))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "process not found: unknown", decodeError(t, rec).Message)
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/event-invoke/failing", strings.NewReader(body)))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "process failed", decodeError(t, rec).Message)
+
+	assert.Equal(t, []string{"stub:a.xlsx", "fallback:a.xlsx", "failing:a.xlsx"}, invoked)
+}
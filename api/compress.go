@@ -0,0 +1,145 @@
+package api
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+func (x *API) Compress(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		encoding := negotiate(req.Header.Get("Accept-Encoding"))
+		if encoding == "" {
+			next.ServeHTTP(w, req)
+			return
+		}
+		w.Header().Add("Vary", "Accept-Encoding")
+		cw := &compressWriter{
+			ResponseWriter: w,
+			encoding:       encoding,
+			threshold:      x.V.Compress.Threshold,
+		}
+		defer func() {
+			if err := recover(); err != nil {
+				panic(err)
+			}
+			cw.Close()
+		}()
+		next.ServeHTTP(cw, req)
+	})
+}
+
+func negotiate(header string) string {
+	accepted := make(map[string]bool)
+	for _, v := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(v, ";")
+		q := 1.0
+		if _, value, ok := strings.Cut(params, "q="); ok {
+			q, _ = strconv.ParseFloat(strings.TrimSpace(value), 64)
+		}
+		accepted[strings.ToLower(strings.TrimSpace(name))] = q > 0
+	}
+	for _, encoding := range []string{"gzip", "deflate"} {
+		if accepted[encoding] {
+			return encoding
+		}
+	}
+	return ""
+}
+
+type compressWriter struct {
+	http.ResponseWriter
+	encoding  string
+	threshold int
+	status    int
+	buf       []byte
+	writer    io.WriteCloser
+}
+
+func (w *compressWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *compressWriter) Write(b []byte) (n int, err error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.writer != nil {
+		return w.writer.Write(b)
+	}
+	w.buf = append(w.buf, b...)
+	if len(w.buf) >= w.threshold {
+		if err = w.start(); err != nil {
+			return
+		}
+	}
+	return len(b), nil
+}
+
+func (w *compressWriter) start() (err error) {
+	h := w.Header()
+	if h.Get("Content-Encoding") != "" {
+		w.writer = nopCloser{w.ResponseWriter}
+	} else {
+		if _, ok := h["Content-Type"]; !ok {
+			h.Set("Content-Type", http.DetectContentType(w.buf))
+		}
+		h.Set("Content-Encoding", w.encoding)
+		h.Del("Content-Length")
+		switch w.encoding {
+		case "gzip":
+			w.writer = gzip.NewWriter(w.ResponseWriter)
+		case "deflate":
+			w.writer = zlib.NewWriter(w.ResponseWriter)
+		}
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	_, err = w.writer.Write(w.buf)
+	w.buf = nil
+	return
+}
+
+func (w *compressWriter) Close() (err error) {
+	if w.writer != nil {
+		return w.writer.Close()
+	}
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	if len(w.buf) != 0 {
+		_, err = w.ResponseWriter.Write(w.buf)
+	}
+	return
+}
+
+func (w *compressWriter) Flush() {
+	if w.writer == nil {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		if err := w.start(); err != nil {
+			return
+		}
+	}
+	if f, ok := w.writer.(interface{ Flush() error }); ok {
+		f.Flush()
+	}
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
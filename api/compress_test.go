@@ -0,0 +1,148 @@
+package api
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"github.com/stretchr/testify/assert"
+	"github.com/weplanx/fn/common"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	cases := []struct {
+		header   string
+		encoding string
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"deflate", "deflate"},
+		{"deflate, gzip", "gzip"},
+		{"GZIP", "gzip"},
+		{"gzip;q=0, deflate", "deflate"},
+		{"gzip; q=0.0, deflate;q=0", ""},
+		{"gzip;q=0.5", "gzip"},
+		{"br, zstd", ""},
+		{"identity", ""},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.encoding, negotiate(c.header), c.header)
+	}
+}
+
+func compressAPI(threshold int) *API {
+	v := new(common.Values)
+	v.Compress.Threshold = threshold
+	return &API{Inject: &common.Inject{V: v}}
+}
+
+func TestAPI_CompressThreshold(t *testing.T) {
+	x := compressAPI(16)
+	body := strings.Repeat("<html>fn</html>", 4)
+	h := x.Compress(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		io.WriteString(w, req.URL.Query().Get("body"))
+	}))
+
+	req := httptest.NewRequest("GET", "/?body=small", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	assert.Equal(t, "small", rec.Body.String())
+
+	req = httptest.NewRequest("GET", "/?body="+body, nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+	r, err := gzip.NewReader(rec.Body)
+	if !assert.NoError(t, err) {
+		return
+	}
+	b, _ := io.ReadAll(r)
+	assert.Equal(t, body, string(b))
+
+	req = httptest.NewRequest("GET", "/?body="+body, nil)
+	req.Header.Set("Accept-Encoding", "deflate")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, "deflate", rec.Header().Get("Content-Encoding"))
+	zr, err := zlib.NewReader(rec.Body)
+	if !assert.NoError(t, err) {
+		return
+	}
+	b, _ = io.ReadAll(zr)
+	assert.Equal(t, body, string(b))
+}
+
+func TestAPI_CompressContentType(t *testing.T) {
+	x := compressAPI(1)
+	h := x.Compress(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"ok":true}`)
+	}))
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+}
+
+func TestAPI_CompressPassthrough(t *testing.T) {
+	x := compressAPI(1)
+	h := x.Compress(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Encoding", "br")
+		w.WriteHeader(http.StatusAccepted)
+		io.WriteString(w, "already encoded")
+	}))
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, "br", rec.Header().Get("Content-Encoding"))
+	assert.Equal(t, "already encoded", rec.Body.String())
+}
+
+func TestAPI_CompressPanic(t *testing.T) {
+	x := compressAPI(1)
+	h := x.Compress(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		panic("boom")
+	}))
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	assert.PanicsWithValue(t, "boom", func() {
+		h.ServeHTTP(rec, req)
+	})
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	assert.Zero(t, rec.Body.Len())
+	assert.False(t, rec.Flushed)
+}
+
+func TestAPI_CompressFlush(t *testing.T) {
+	x := compressAPI(1024)
+	h := x.Compress(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		io.WriteString(w, "event: ping\n\n")
+		assert.NoError(t, http.NewResponseController(w).Flush())
+	}))
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.True(t, rec.Flushed)
+	assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	r, err := gzip.NewReader(rec.Body)
+	if !assert.NoError(t, err) {
+		return
+	}
+	b, _ := io.ReadAll(r)
+	assert.Equal(t, "event: ping\n\n", string(b))
+}
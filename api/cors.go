@@ -0,0 +1,73 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+func (x *API) Cors(next http.Handler) http.Handler {
+	option := x.V.Cors
+	methods := strings.Join(option.Methods, ", ")
+	headers := strings.Join(option.Headers, ", ")
+	maxAge := strconv.Itoa(int(option.MaxAge.Seconds()))
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		origin := req.Header.Get("Origin")
+		if origin == "" || len(option.Origins) == 0 {
+			next.ServeHTTP(w, req)
+			return
+		}
+		h := w.Header()
+		h.Add("Vary", "Origin")
+		ok, wildcard := matchOrigin(option.Origins, origin)
+		if !ok {
+			next.ServeHTTP(w, req)
+			return
+		}
+		if wildcard {
+			h.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			h.Set("Access-Control-Allow-Origin", origin)
+			if option.Credentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+		}
+		if req.Method != http.MethodOptions || req.Header.Get("Access-Control-Request-Method") == "" {
+			next.ServeHTTP(w, req)
+			return
+		}
+		h.Add("Vary", "Access-Control-Request-Method")
+		h.Add("Vary", "Access-Control-Request-Headers")
+		h.Set("Access-Control-Allow-Methods", methods)
+		if headers != "" {
+			h.Set("Access-Control-Allow-Headers", headers)
+		} else if v := req.Header.Get("Access-Control-Request-Headers"); v != "" {
+			h.Set("Access-Control-Allow-Headers", v)
+		}
+		h.Set("Access-Control-Max-Age", maxAge)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func matchOrigin(origins []string, origin string) (ok bool, wildcard bool) {
+	for _, v := range origins {
+		if v == "*" {
+			wildcard = true
+			continue
+		}
+		if v == origin {
+			return true, false
+		}
+		before, after, ok := strings.Cut(v, "*")
+		if !ok || len(origin) <= len(before)+len(after) {
+			continue
+		}
+		if strings.HasPrefix(origin, before) && strings.HasSuffix(origin, after) {
+			sub := origin[len(before) : len(origin)-len(after)]
+			if !strings.ContainsAny(sub, "/:") {
+				return true, false
+			}
+		}
+	}
+	return wildcard, wildcard
+}
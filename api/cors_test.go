@@ -0,0 +1,89 @@
+package api
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/weplanx/fn/common"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMatchOrigin(t *testing.T) {
+	origins := []string{"https://*.example.com", "http://localhost:4200"}
+	cases := []struct {
+		origin string
+		ok     bool
+	}{
+		{"https://a.example.com", true},
+		{"https://a.b.example.com", true},
+		{"https://.example.com", false},
+		{"https://example.com", false},
+		{"http://a.example.com", false},
+		{"https://a.example.com:8443", false},
+		{"https://evil.com/.example.com", false},
+		{"https://evil.com:.example.com", false},
+		{"http://localhost:4200", true},
+		{"http://localhost:4300", false},
+		{"http://localhost", false},
+	}
+	for _, c := range cases {
+		ok, wildcard := matchOrigin(origins, c.origin)
+		assert.Equal(t, c.ok, ok, c.origin)
+		assert.False(t, wildcard, c.origin)
+	}
+
+	ok, wildcard := matchOrigin([]string{"*", "https://app.example.com"}, "https://app.example.com")
+	assert.True(t, ok)
+	assert.False(t, wildcard)
+	ok, wildcard = matchOrigin([]string{"*", "https://app.example.com"}, "https://evil.example")
+	assert.True(t, ok)
+	assert.True(t, wildcard)
+}
+
+func TestAPI_CorsAnyWithCredentials(t *testing.T) {
+	v := new(common.Values)
+	v.Cors.Origins = []string{"*", "https://app.example.com"}
+	v.Cors.Methods = []string{"GET", "POST"}
+	v.Cors.Credentials = true
+	x := &API{Inject: &common.Inject{V: v}}
+	h := x.Cors(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Origin", "https://evil.example")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Credentials"))
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+func TestAPI_CorsVary(t *testing.T) {
+	v := new(common.Values)
+	v.Cors.Origins = []string{"https://app.example.com"}
+	x := &API{Inject: &common.Inject{V: v}}
+	h := x.Cors(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Origin", "https://evil.example")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, []string{"Origin"}, rec.Header().Values("Vary"))
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, []string{"Origin"}, rec.Header().Values("Vary"))
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assert.Empty(t, rec.Header().Values("Vary"))
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/bytedance/sonic/encoder"
+	"net/http"
+	"strings"
+)
+
+type Error struct {
+	Code      string      `json:"code"`
+	Message   string      `json:"message"`
+	Details   interface{} `json:"details,omitempty"`
+	RequestId string      `json:"request_id,omitempty"`
+}
+
+func (e Error) Error() string {
+	return e.Message
+}
+
+func Fail(w http.ResponseWriter, req *http.Request, status int, err error) {
+	var e Error
+	if !errors.As(err, &e) {
+		e.Message = err.Error()
+	}
+	if e.Code == "" {
+		e.Code = strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+	}
+	e.RequestId = req.Header.Get("X-Request-Id")
+	h := w.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Type", "application/json; charset=utf-8")
+	h.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	encoder.NewStreamEncoder(w).Encode(e)
+}
+
+func (x *API) RequestId(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		id := req.Header.Get("X-Request-Id")
+		if id == "" {
+			b := make([]byte, 16)
+			rand.Read(b)
+			id = hex.EncodeToString(b)
+			req.Header.Set("X-Request-Id", id)
+		}
+		w.Header().Set("X-Request-Id", id)
+		next.ServeHTTP(w, req)
+	})
+}
+
+func (x *API) NotFound(w http.ResponseWriter, req *http.Request) {
+	Fail(w, req, http.StatusNotFound, fmt.Errorf(`route not found: %s`, req.URL.Path))
+}
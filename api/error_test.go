@@ -0,0 +1,80 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/bytedance/sonic/decoder"
+	"github.com/stretchr/testify/assert"
+	"github.com/weplanx/fn/common"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func decodeError(t *testing.T, rec *httptest.ResponseRecorder) (e Error) {
+	if err := decoder.NewStreamDecoder(rec.Body).Decode(&e); err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func TestFail(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Request-Id", "abc")
+	rec := httptest.NewRecorder()
+	rec.Header().Set("Content-Length", "42")
+	Fail(rec, req, http.StatusServiceUnavailable, errors.New("down"))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+	assert.Empty(t, rec.Header().Get("Content-Length"))
+	assert.Equal(t, Error{Code: "service_unavailable", Message: "down", RequestId: "abc"}, decodeError(t, rec))
+
+	rec = httptest.NewRecorder()
+	err := fmt.Errorf("wrapped: %w", Error{Code: "invalid_key", Message: "bad key", Details: M{"field": "key"}})
+	Fail(rec, httptest.NewRequest("GET", "/", nil), http.StatusBadRequest, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, Error{Code: "invalid_key", Message: "bad key", Details: M{"field": "key"}}, decodeError(t, rec))
+	assert.NotContains(t, rec.Body.String(), "request_id")
+}
+
+func TestAPI_RequestId(t *testing.T) {
+	x := &API{Inject: &common.Inject{V: new(common.Values)}}
+	h := x.RequestId(http.HandlerFunc(x.NotFound))
+
+	req := httptest.NewRequest("GET", "/missing", nil)
+	req.Header.Set("X-Request-Id", "abc")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "abc", rec.Header().Get("X-Request-Id"))
+	assert.Equal(t, Error{Code: "not_found", Message: "route not found: /missing", RequestId: "abc"}, decodeError(t, rec))
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/missing", nil))
+	id := rec.Header().Get("X-Request-Id")
+	assert.Len(t, id, 32)
+	assert.Equal(t, id, decodeError(t, rec).RequestId)
+}
+
+func TestAPI_EventInvokeErrors(t *testing.T) {
+	x := &API{Inject: &common.Inject{V: new(common.Values)}}
+
+	rec := httptest.NewRecorder()
+	x.EventInvoke(rec, httptest.NewRequest("GET", "/event-invoke", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, "POST", rec.Header().Get("Allow"))
+	assert.Equal(t, "method_not_allowed", decodeError(t, rec).Code)
+
+	rec = httptest.NewRecorder()
+	x.EventInvoke(rec, httptest.NewRequest("POST", "/event-invoke", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, Error{Code: "not_found", Message: "process not found: "}, decodeError(t, rec))
+
+	x.V.Process = "tencent-cos-excel"
+	rec = httptest.NewRecorder()
+	x.EventInvoke(rec, httptest.NewRequest("POST", "/event-invoke", strings.NewReader("{")))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "bad_request", decodeError(t, rec).Code)
+}
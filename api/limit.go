@@ -0,0 +1,34 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+func (x *API) Limit(next http.Handler) http.Handler {
+	max := x.V.Server.MaxBodySize
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if max > 0 {
+			if req.ContentLength > max {
+				Fail(w, req, http.StatusRequestEntityTooLarge, fmt.Errorf(`request body exceeds limit: %d`, max))
+				return
+			}
+			req.Body = http.MaxBytesReader(w, req.Body, max)
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+func readStatus(err error) int {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return http.StatusRequestEntityTooLarge
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return http.StatusRequestTimeout
+	}
+	return http.StatusBadRequest
+}
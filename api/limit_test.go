@@ -0,0 +1,59 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/weplanx/fn/common"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAPI_Limit(t *testing.T) {
+	v := new(common.Values)
+	v.Server.MaxBodySize = 8
+	x := &API{Inject: &common.Inject{V: v}}
+	h := x.Limit(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if _, err := io.ReadAll(req.Body); err != nil {
+			Fail(w, req, readStatus(err), err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/", strings.NewReader("12345678")))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/", strings.NewReader("123456789")))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"code":"request_entity_too_large"`)
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader("123456789"))
+	req.ContentLength = -1
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"code":"request_entity_too_large"`)
+
+	v.Server.MaxBodySize = 0
+	h = x.Limit(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		b, _ := io.ReadAll(req.Body)
+		w.Write(b)
+	}))
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/", strings.NewReader("123456789")))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "123456789", rec.Body.String())
+}
+
+func TestReadStatus(t *testing.T) {
+	assert.Equal(t, http.StatusRequestEntityTooLarge, readStatus(&http.MaxBytesError{Limit: 8}))
+	assert.Equal(t, http.StatusRequestTimeout, readStatus(fmt.Errorf("read: %w", os.ErrDeadlineExceeded)))
+	assert.Equal(t, http.StatusBadRequest, readStatus(errors.New("invalid json")))
+}
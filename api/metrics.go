@@ -0,0 +1,99 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	requests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "fn_http_requests_total",
+		Help: "Total number of HTTP requests by route and status.",
+	}, []string{"method", "route", "status"})
+	durations = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "fn_http_request_duration_seconds",
+		Help:    "HTTP request latencies in seconds by route.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+	methods = []string{"GET", "POST", "PUT", "DELETE", "HEAD", "OPTIONS", "PATCH"}
+)
+
+func (x *API) Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		rw := &statusWriter{ResponseWriter: w}
+		next.ServeHTTP(rw, req)
+		if rw.status == 0 {
+			rw.status = http.StatusOK
+		}
+		r := req.Pattern
+		if r == "" {
+			r = "unmatched"
+		}
+		m := req.Method
+		if !slices.Contains(methods, m) {
+			m = "other"
+		}
+		span := trace.SpanFromContext(req.Context())
+		span.SetName(m + " " + r)
+		span.SetAttributes(semconv.HTTPRoute(r))
+		requests.WithLabelValues(m, r, strconv.Itoa(rw.status)).Inc()
+		durations.WithLabelValues(m, r).Observe(time.Since(start).Seconds())
+	})
+}
+
+func (x *API) Exporter() (_ http.Handler, err error) {
+	allows := make([]netip.Prefix, 0, len(x.V.Metrics.Allows))
+	for _, v := range x.V.Metrics.Allows {
+		var prefix netip.Prefix
+		if strings.Contains(v, "/") {
+			prefix, err = netip.ParsePrefix(v)
+		} else {
+			var addr netip.Addr
+			addr, err = netip.ParseAddr(v)
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		if err != nil {
+			return nil, fmt.Errorf(`invalid metrics allow: %s`, v)
+		}
+		allows = append(allows, prefix.Masked())
+	}
+	handler := promhttp.Handler()
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if len(allows) != 0 && !allowed(allows, req.RemoteAddr) {
+			Fail(w, req, http.StatusForbidden, errors.New(http.StatusText(http.StatusForbidden)))
+			return
+		}
+		handler.ServeHTTP(w, req)
+	}), nil
+}
+
+func allowed(allows []netip.Prefix, remote string) bool {
+	host, _, err := net.SplitHostPort(remote)
+	if err != nil {
+		host = remote
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range allows {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/weplanx/fn/common"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPI_MetricsMethod(t *testing.T) {
+	x := &API{Inject: &common.Inject{V: new(common.Values)}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics-method", func(w http.ResponseWriter, req *http.Request) {})
+	h := x.Metrics(mux)
+
+	for _, method := range []string{"AAA1", "AAA2", "GET"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, "/metrics-method", nil))
+	}
+	assert.Equal(t, float64(2), testutil.ToFloat64(requests.WithLabelValues("other", "/metrics-method", "200")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(requests.WithLabelValues("GET", "/metrics-method", "200")))
+	assert.Equal(t, 0, testutil.CollectAndCount(requests, "AAA1"))
+}
+
+func TestAPI_MetricsSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	x := &API{Inject: &common.Inject{V: new(common.Values)}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics-span/{name}", func(w http.ResponseWriter, req *http.Request) {})
+	h := otelhttp.NewHandler(x.Metrics(mux), "fn",
+		otelhttp.WithTracerProvider(provider),
+		otelhttp.WithPropagators(propagation.TraceContext{}),
+	)
+
+	req := httptest.NewRequest("GET", "/metrics-span/a", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	if !assert.Len(t, spans, 1) {
+		return
+	}
+	assert.Equal(t, "GET /metrics-span/{name}", spans[0].Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent().SpanID().String())
+}
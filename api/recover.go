@@ -0,0 +1,69 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+func (x *API) Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		hub := x.Sentry.Clone()
+		hub.Scope().SetRequest(req)
+		if id := req.Header.Get("X-Request-Id"); id != "" {
+			hub.Scope().SetTag("request_id", id)
+		}
+		rw := &statusWriter{ResponseWriter: w}
+		defer func() {
+			if err := recover(); err != nil {
+				if err != http.ErrAbortHandler {
+					hub.Scope().SetTag("route", route(req))
+					hub.RecoverWithContext(req.Context(), err)
+				}
+				if err == http.ErrAbortHandler || rw.status != 0 {
+					panic(http.ErrAbortHandler)
+				}
+				Fail(w, req, http.StatusInternalServerError, errors.New(http.StatusText(http.StatusInternalServerError)))
+			}
+		}()
+		next.ServeHTTP(rw, req)
+		if rw.status >= 500 {
+			hub.Scope().SetTag("route", route(req))
+			hub.CaptureMessage(fmt.Sprintf(`%s %s %d: %s`, req.Method, req.URL.Path, rw.status, rw.body))
+		}
+	})
+}
+
+func route(req *http.Request) string {
+	if req.Pattern != "" {
+		return req.Pattern
+	}
+	return req.URL.Path
+}
+
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	body   []byte
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.status >= 500 && len(w.body) < 1024 {
+		w.body = append(w.body, b[:min(len(b), 1024-len(w.body))]...)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
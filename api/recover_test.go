@@ -0,0 +1,26 @@
+package api
+
+import (
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/weplanx/fn/common"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPI_RecoverFlush(t *testing.T) {
+	x := &API{Inject: &common.Inject{
+		V:      new(common.Values),
+		Sentry: sentry.NewHub(nil, sentry.NewScope()),
+	}}
+	h := x.Metrics(x.Recover(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("chunk"))
+		assert.NoError(t, http.NewResponseController(w).Flush())
+	})))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assert.True(t, rec.Flushed)
+	assert.Equal(t, "chunk", rec.Body.String())
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"github.com/bytedance/sonic/encoder"
+	"github.com/tencentyun/cos-go-sdk-v5"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Presigned struct {
+	Url     string    `json:"url"`
+	Method  string    `json:"method"`
+	Expires time.Time `json:"expires"`
+}
+
+func (x *API) StoragePresign(w http.ResponseWriter, req *http.Request) {
+	if req.Method != "GET" {
+		w.Header().Set("Allow", "GET")
+		Fail(w, req, http.StatusMethodNotAllowed, fmt.Errorf(`method not allowed: %s`, req.Method))
+		return
+	}
+	option := x.V.Storage
+	if option.Prefix == "" || option.Token == "" {
+		Fail(w, req, http.StatusNotFound, errors.New(`storage presign is not configured`))
+		return
+	}
+	token := req.Header.Get("X-Storage-Token")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(option.Token)) != 1 {
+		Fail(w, req, http.StatusUnauthorized, errors.New(`invalid storage token`))
+		return
+	}
+	ctx := req.Context()
+	query := req.URL.Query()
+	method := strings.ToUpper(query.Get("method"))
+	key := query.Get("key")
+
+	header, err := x.StoragePolicy(method, key, query)
+	if err != nil {
+		Fail(w, req, http.StatusBadRequest, err)
+		return
+	}
+
+	expired := option.Expired
+	var u *url.URL
+	if u, err = x.Client.Object.GetPresignedURL2(ctx, method, key, expired, &cos.PresignedURLOptions{
+		Header: &header,
+	}); err != nil {
+		Fail(w, req, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	encoder.NewStreamEncoder(w).Encode(Presigned{
+		Url:     u.String(),
+		Method:  method,
+		Expires: time.Now().Add(expired),
+	})
+}
+
+func (x *API) StoragePolicy(method string, key string, query url.Values) (header http.Header, err error) {
+	header = make(http.Header)
+	policy := x.V.Storage
+	if policy.Prefix == "" {
+		return nil, errors.New(`storage prefix is not configured`)
+	}
+	if method != "GET" && method != "PUT" || !slices.Contains(policy.Methods, method) {
+		return nil, Error{Message: fmt.Sprintf(`unsupported method: %s`, method), Details: M{"field": "method"}}
+	}
+	prefix := policy.Prefix
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	if key == "" || path.Clean(key) != key || !strings.HasPrefix(key, prefix) {
+		return nil, Error{Message: fmt.Sprintf(`key must start with: %s`, prefix), Details: M{"field": "key"}}
+	}
+	if method == "GET" {
+		return
+	}
+	if policy.MaxSize <= 0 {
+		return nil, errors.New(`storage max size is not configured`)
+	}
+	contentType := query.Get("content_type")
+	if len(policy.ContentTypes) != 0 && !slices.Contains(policy.ContentTypes, contentType) {
+		return nil, Error{Message: fmt.Sprintf(`content type not allowed: %s`, contentType), Details: M{"field": "content_type"}}
+	}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	var size int64
+	if size, err = strconv.ParseInt(query.Get("size"), 10, 64); err != nil || size <= 0 {
+		return nil, Error{Message: `size is required`, Details: M{"field": "size"}}
+	}
+	if size > policy.MaxSize {
+		return nil, Error{Message: fmt.Sprintf(`size exceeds limit: %d`, policy.MaxSize), Details: M{"field": "size"}}
+	}
+	header.Set("Content-Length", strconv.FormatInt(size, 10))
+	return
+}
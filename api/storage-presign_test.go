@@ -0,0 +1,110 @@
+package api
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/weplanx/fn/common"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestAPI_StoragePolicy(t *testing.T) {
+	v := new(common.Values)
+	v.Storage.Prefix = "uploads"
+	v.Storage.Methods = []string{"GET", "PUT"}
+	v.Storage.ContentTypes = []string{"image/png"}
+	v.Storage.MaxSize = 1024
+	x := &API{Inject: &common.Inject{V: v}}
+
+	cases := []struct {
+		name   string
+		method string
+		key    string
+		query  url.Values
+		field  string
+		header map[string]string
+	}{
+		{name: "get", method: "GET", key: "uploads/a.png"},
+		{name: "put", method: "PUT", key: "uploads/a.png",
+			query:  url.Values{"content_type": {"image/png"}, "size": {"512"}},
+			header: map[string]string{"Content-Type": "image/png", "Content-Length": "512"}},
+		{name: "unsupported method", method: "DELETE", key: "uploads/a.png", field: "method"},
+		{name: "empty key", method: "GET", key: "", field: "key"},
+		{name: "outside prefix", method: "GET", key: "exports/a.xlsx", field: "key"},
+		{name: "sibling prefix", method: "GET", key: "uploads-private/a.png", field: "key"},
+		{name: "bare prefix", method: "GET", key: "uploads", field: "key"},
+		{name: "dot segments", method: "GET", key: "uploads/../a.xlsx", field: "key"},
+		{name: "current segment", method: "GET", key: "uploads/./a.png", field: "key"},
+		{name: "content type", method: "PUT", key: "uploads/a.png",
+			query: url.Values{"content_type": {"text/html"}, "size": {"512"}}, field: "content_type"},
+		{name: "missing size", method: "PUT", key: "uploads/a.png",
+			query: url.Values{"content_type": {"image/png"}}, field: "size"},
+		{name: "size limit", method: "PUT", key: "uploads/a.png",
+			query: url.Values{"content_type": {"image/png"}, "size": {"2048"}}, field: "size"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			header, err := x.StoragePolicy(c.method, c.key, c.query)
+			if c.field != "" {
+				var e Error
+				if assert.ErrorAs(t, err, &e) {
+					assert.Equal(t, M{"field": c.field}, e.Details)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			for k, v := range c.header {
+				assert.Equal(t, v, header.Get(k))
+			}
+		})
+	}
+}
+
+func TestAPI_StoragePolicyWithoutPrefix(t *testing.T) {
+	x := &API{Inject: &common.Inject{V: new(common.Values)}}
+	_, err := x.StoragePolicy("GET", "a.xlsx", nil)
+	assert.Error(t, err)
+}
+
+func TestAPI_StoragePolicyDefaults(t *testing.T) {
+	v := new(common.Values)
+	v.Storage.Prefix = "uploads/"
+	v.Storage.Methods = []string{"PUT"}
+	x := &API{Inject: &common.Inject{V: v}}
+
+	_, err := x.StoragePolicy("GET", "uploads/a.png", nil)
+	var e Error
+	if assert.ErrorAs(t, err, &e) {
+		assert.Equal(t, M{"field": "method"}, e.Details)
+	}
+
+	_, err = x.StoragePolicy("PUT", "uploads/a.png", url.Values{"size": {"512"}})
+	assert.EqualError(t, err, "storage max size is not configured")
+}
+
+func TestAPI_StoragePresignToken(t *testing.T) {
+	v := new(common.Values)
+	v.Storage.Prefix = "uploads/"
+	x := &API{Inject: &common.Inject{V: v}}
+
+	rec := httptest.NewRecorder()
+	x.StoragePresign(rec, httptest.NewRequest("GET", "/storage/presign", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	v.Storage.Token = "secret"
+	rec = httptest.NewRecorder()
+	x.StoragePresign(rec, httptest.NewRequest("GET", "/storage/presign", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	req := httptest.NewRequest("GET", "/storage/presign", nil)
+	req.Header.Set("X-Storage-Token", "wrong")
+	rec = httptest.NewRecorder()
+	x.StoragePresign(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = httptest.NewRecorder()
+	x.StoragePresign(rec, httptest.NewRequest("POST", "/storage/presign", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, "GET", rec.Header().Get("Allow"))
+}
@@ -1,11 +1,28 @@
 package bootstrap
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"github.com/caarlos0/env/v10"
+	"github.com/getsentry/sentry-go"
 	"github.com/tencentyun/cos-go-sdk-v5"
 	"github.com/weplanx/fn/common"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	"net/http"
 	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
 )
 
 func LoadStaticValues() (values *common.Values, err error) {
@@ -23,7 +40,138 @@ func UseCos(values *common.Values) (client *cos.Client, err error) {
 		Transport: &cos.AuthorizationTransport{
 			SecretID:  values.Cos.SecretId,
 			SecretKey: values.Cos.SecretKey,
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
 		},
 	})
 	return
 }
+
+func UseSentry(values *common.Values) (hub *sentry.Hub, err error) {
+	var client *sentry.Client
+	if client, err = sentry.NewClient(sentry.ClientOptions{
+		Dsn:         values.Sentry.Dsn,
+		Environment: values.Sentry.Environment,
+		SampleRate:  values.Sentry.SampleRate,
+	}); err != nil {
+		return
+	}
+	hub = sentry.NewHub(client, sentry.NewScope())
+	return
+}
+
+func UseOtel(values *common.Values) (provider *trace.TracerProvider, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	option := values.Otel
+	if option.Endpoint == "" {
+		return
+	}
+	options := []otlptracehttp.Option{otlptracehttp.WithEndpointURL(option.Endpoint)}
+	if option.Insecure {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	var exporter *otlptrace.Exporter
+	if exporter, err = otlptracehttp.New(context.Background(), options...); err != nil {
+		return
+	}
+	provider = trace.NewTracerProvider(
+		trace.WithBatcher(exporter),
+		trace.WithResource(resource.NewSchemaless(
+			semconv.ServiceName(option.ServiceName),
+		)),
+		trace.WithSampler(trace.ParentBased(trace.TraceIDRatioBased(option.SampleRate))),
+	)
+	otel.SetTracerProvider(provider)
+	return
+}
+
+func UseTLS(values *common.Values) (config *tls.Config, err error) {
+	option := values.TLS
+	if option.Cert == "" && option.Key == "" && option.ClientCA == "" {
+		return
+	}
+	if option.Cert == "" || option.Key == "" {
+		return nil, errors.New(`tls requires both TLS_CERT and TLS_KEY`)
+	}
+	loader := &certLoader{certFile: option.Cert, keyFile: option.Key}
+	if err = loader.load(); err != nil {
+		return
+	}
+	config = &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: loader.GetCertificate,
+	}
+	if option.ClientCA != "" {
+		var b []byte
+		if b, err = readPEM(option.ClientCA); err != nil {
+			return
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, errors.New(`invalid tls client ca`)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return
+}
+
+func readPEM(v string) ([]byte, error) {
+	if strings.HasPrefix(strings.TrimSpace(v), "-----BEGIN") {
+		return []byte(v), nil
+	}
+	return os.ReadFile(v)
+}
+
+type certLoader struct {
+	mu       sync.RWMutex
+	certFile string
+	keyFile  string
+	modified time.Time
+	cert     *tls.Certificate
+}
+
+func (x *certLoader) modTime() (t time.Time) {
+	for _, name := range []string{x.certFile, x.keyFile} {
+		if info, err := os.Stat(name); err == nil && info.ModTime().After(t) {
+			t = info.ModTime()
+		}
+	}
+	return
+}
+
+func (x *certLoader) load() (err error) {
+	modified := x.modTime()
+	var certPEM, keyPEM []byte
+	if certPEM, err = readPEM(x.certFile); err != nil {
+		return
+	}
+	if keyPEM, err = readPEM(x.keyFile); err != nil {
+		return
+	}
+	var cert tls.Certificate
+	if cert, err = tls.X509KeyPair(certPEM, keyPEM); err != nil {
+		return
+	}
+	x.mu.Lock()
+	x.cert = &cert
+	x.modified = modified
+	x.mu.Unlock()
+	return
+}
+
+func (x *certLoader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	x.mu.RLock()
+	cert, modified := x.cert, x.modified
+	x.mu.RUnlock()
+	if !x.modTime().Equal(modified) {
+		if err := x.load(); err == nil {
+			x.mu.RLock()
+			cert = x.cert
+			x.mu.RUnlock()
+		}
+	}
+	return cert, nil
+}
@@ -0,0 +1,169 @@
+package bootstrap
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/stretchr/testify/assert"
+	"github.com/weplanx/fn/common"
+	"go.opentelemetry.io/otel"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func generate(t *testing.T) (der []byte, certPEM []byte, keyPEM []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: "fn.test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	if der, err = x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key); err != nil {
+		t.Fatal(err)
+	}
+	b, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: b})
+	return
+}
+
+func write(t *testing.T, name string, data []byte, modified time.Time) {
+	if err := os.WriteFile(name, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(name, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUseTLSPartial(t *testing.T) {
+	values := new(common.Values)
+	config, err := UseTLS(values)
+	assert.NoError(t, err)
+	assert.Nil(t, config)
+
+	_, certPEM, keyPEM := generate(t)
+	values.TLS.Cert = string(certPEM)
+	_, err = UseTLS(values)
+	assert.EqualError(t, err, "tls requires both TLS_CERT and TLS_KEY")
+
+	values.TLS.Cert = ""
+	values.TLS.Key = string(keyPEM)
+	_, err = UseTLS(values)
+	assert.EqualError(t, err, "tls requires both TLS_CERT and TLS_KEY")
+
+	values.TLS.Key = ""
+	values.TLS.ClientCA = string(certPEM)
+	_, err = UseTLS(values)
+	assert.EqualError(t, err, "tls requires both TLS_CERT and TLS_KEY")
+}
+
+func TestUseTLSInline(t *testing.T) {
+	der, certPEM, keyPEM := generate(t)
+	values := new(common.Values)
+	values.TLS.Cert = string(certPEM)
+	values.TLS.Key = string(keyPEM)
+	config, err := UseTLS(values)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, uint16(tls.VersionTLS12), config.MinVersion)
+	assert.Equal(t, tls.NoClientCert, config.ClientAuth)
+	cert, err := config.GetCertificate(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, der, cert.Certificate[0])
+}
+
+func TestUseTLSClientCA(t *testing.T) {
+	_, certPEM, keyPEM := generate(t)
+	values := new(common.Values)
+	values.TLS.Cert = string(certPEM)
+	values.TLS.Key = string(keyPEM)
+
+	values.TLS.ClientCA = "-----BEGIN CERTIFICATE-----\ninvalid\n-----END CERTIFICATE-----\n"
+	_, err := UseTLS(values)
+	assert.EqualError(t, err, "invalid tls client ca")
+
+	values.TLS.ClientCA = filepath.Join(t.TempDir(), "missing.pem")
+	_, err = UseTLS(values)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	values.TLS.ClientCA = string(certPEM)
+	config, err := UseTLS(values)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, tls.RequireAndVerifyClientCert, config.ClientAuth)
+	assert.NotNil(t, config.ClientCAs)
+}
+
+func TestUseTLSReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	modified := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	der1, certPEM1, keyPEM1 := generate(t)
+	write(t, certFile, certPEM1, modified)
+	write(t, keyFile, keyPEM1, modified)
+	values := new(common.Values)
+	values.TLS.Cert = certFile
+	values.TLS.Key = keyFile
+	config, err := UseTLS(values)
+	if !assert.NoError(t, err) {
+		return
+	}
+	cert, err := config.GetCertificate(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, der1, cert.Certificate[0])
+
+	der2, certPEM2, keyPEM2 := generate(t)
+	modified = modified.Add(time.Minute)
+	write(t, certFile, certPEM2, modified)
+	write(t, keyFile, keyPEM2, modified)
+	cert, err = config.GetCertificate(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, der2, cert.Certificate[0])
+
+	_, certPEM3, _ := generate(t)
+	write(t, certFile, certPEM3, modified.Add(time.Minute))
+	cert, err = config.GetCertificate(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, der2, cert.Certificate[0])
+}
+
+func TestUseOtel(t *testing.T) {
+	values := new(common.Values)
+	provider, err := UseOtel(values)
+	assert.NoError(t, err)
+	assert.Nil(t, provider)
+	assert.Contains(t, otel.GetTextMapPropagator().Fields(), "traceparent")
+
+	values.Otel.Endpoint = "http://localhost:4318"
+	values.Otel.ServiceName = "fn"
+	values.Otel.SampleRate = 1
+	provider, err = UseOtel(values)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotNil(t, provider)
+	assert.NoError(t, provider.Shutdown(context.Background()))
+}
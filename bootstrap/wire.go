@@ -15,6 +15,8 @@ func NewAPI() (*api.API, error) {
 		wire.Struct(new(common.Inject), "*"),
 		LoadStaticValues,
 		UseCos,
+		UseSentry,
+		UseOtel,
 	)
 	return &api.API{}, nil
 }
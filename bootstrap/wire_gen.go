@@ -22,9 +22,19 @@ func NewAPI() (*api.API, error) {
 	if err != nil {
 		return nil, err
 	}
+	hub, err := UseSentry(values)
+	if err != nil {
+		return nil, err
+	}
+	tracerProvider, err := UseOtel(values)
+	if err != nil {
+		return nil, err
+	}
 	inject := &common.Inject{
 		V:      values,
 		Client: client,
+		Sentry: hub,
+		Otel:   tracerProvider,
 	}
 	apiAPI := &api.API{
 		Inject: inject,
@@ -1,10 +1,17 @@
 package common
 
-import "github.com/tencentyun/cos-go-sdk-v5"
+import (
+	"github.com/getsentry/sentry-go"
+	"github.com/tencentyun/cos-go-sdk-v5"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"time"
+)
 
 type Inject struct {
 	V      *Values
 	Client *cos.Client
+	Sentry *sentry.Hub
+	Otel   *trace.TracerProvider
 }
 
 type Values struct {
@@ -15,6 +22,50 @@ type Values struct {
 		SecretId  string `env:"SECRETID"`
 		SecretKey string `env:"SECRETKEY"`
 	} `envPrefix:"COS_"`
+	Compress struct {
+		Threshold int `env:"THRESHOLD" envDefault:"1024"`
+	} `envPrefix:"COMPRESS_"`
+	Storage struct {
+		Expired      time.Duration `env:"EXPIRED" envDefault:"10m"`
+		Prefix       string        `env:"PREFIX"`
+		ContentTypes []string      `env:"CONTENTTYPES"`
+		MaxSize      int64         `env:"MAXSIZE"`
+		Methods      []string      `env:"METHODS" envDefault:"PUT"`
+		Token        string        `env:"TOKEN"`
+	} `envPrefix:"STORAGE_"`
+	Sentry struct {
+		Dsn         string  `env:"DSN"`
+		Environment string  `env:"ENVIRONMENT"`
+		SampleRate  float64 `env:"SAMPLERATE" envDefault:"1"`
+	} `envPrefix:"SENTRY_"`
+	Otel struct {
+		Endpoint    string  `env:"ENDPOINT"`
+		Insecure    bool    `env:"INSECURE"`
+		ServiceName string  `env:"SERVICENAME" envDefault:"fn"`
+		SampleRate  float64 `env:"SAMPLERATE" envDefault:"1"`
+	} `envPrefix:"OTEL_"`
+	Metrics struct {
+		Address string   `env:"ADDRESS"`
+		Allows  []string `env:"ALLOWS"`
+	} `envPrefix:"METRICS_"`
+	Cors struct {
+		Origins     []string      `env:"ORIGINS"`
+		Methods     []string      `env:"METHODS" envDefault:"GET,POST"`
+		Headers     []string      `env:"HEADERS"`
+		MaxAge      time.Duration `env:"MAXAGE" envDefault:"12h"`
+		Credentials bool          `env:"CREDENTIALS"`
+	} `envPrefix:"CORS_"`
+	TLS struct {
+		Cert     string `env:"CERT"`
+		Key      string `env:"KEY"`
+		ClientCA string `env:"CLIENTCA"`
+	} `envPrefix:"TLS_"`
+	Server struct {
+		MaxBodySize       int64         `env:"MAXBODYSIZE" envDefault:"4194304"`
+		MaxHeaderSize     int           `env:"MAXHEADERSIZE" envDefault:"65536"`
+		ReadTimeout       time.Duration `env:"READTIMEOUT" envDefault:"30s"`
+		ReadHeaderTimeout time.Duration `env:"READHEADERTIMEOUT" envDefault:"10s"`
+	} `envPrefix:"SERVER_"`
 }
 
 type ExcelMetadata struct {
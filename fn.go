@@ -9,6 +9,7 @@ import (
 	"github.com/weplanx/fn/common"
 	"net/http"
 	"net/url"
+	"time"
 )
 
 type Fn struct {
@@ -74,3 +75,7 @@ func (x *Fn) TencentCosExcel(ctx context.Context, name string, sheets common.Exc
 	}
 	return
 }
+
+func (x *Fn) TencentCosPresign(ctx context.Context, method string, key string, expired time.Duration) (*url.URL, error) {
+	return x.Cos.Object.GetPresignedURL2(ctx, method, key, expired, nil)
+}
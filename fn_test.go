@@ -7,8 +7,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/weplanx/fn"
 	"github.com/weplanx/fn/common"
+	"net/http"
 	"os"
 	"testing"
+	"time"
 )
 
 var (
@@ -44,3 +46,13 @@ func TestFn_TencentCosExcel(t *testing.T) {
 	})
 	assert.NoError(t, err)
 }
+
+func TestFn_TencentCosPresign(t *testing.T) {
+	ctx := context.TODO()
+	u, err := x.TencentCosPresign(ctx, http.MethodPut, "test.xlsx", time.Minute)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Contains(t, u.Path, "test.xlsx")
+	assert.NotEmpty(t, u.Query().Get("q-signature"))
+}
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"errors"
 	"github.com/weplanx/fn/bootstrap"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"net/http"
 )
 
@@ -11,6 +13,54 @@ func main() {
 		panic(err)
 	}
 
+	exporter, err := api.Exporter()
+	if err != nil {
+		panic(err)
+	}
+	if api.V.Metrics.Address != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", exporter)
+		server := &http.Server{
+			Addr:              api.V.Metrics.Address,
+			Handler:           mux,
+			ReadTimeout:       api.V.Server.ReadTimeout,
+			ReadHeaderTimeout: api.V.Server.ReadHeaderTimeout,
+			MaxHeaderBytes:    api.V.Server.MaxHeaderSize,
+		}
+		go func() {
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				panic(err)
+			}
+		}()
+	} else if len(api.V.Metrics.Allows) != 0 {
+		http.Handle("/metrics", exporter)
+	}
+
+	http.HandleFunc("/", api.NotFound)
 	http.HandleFunc("/event-invoke", api.EventInvoke)
-	http.ListenAndServe(api.V.Address, nil)
+	http.HandleFunc("/event-invoke/{name}", api.EventInvoke)
+	if api.V.Storage.Prefix != "" && api.V.Storage.Token != "" {
+		http.HandleFunc("/storage/presign", api.StoragePresign)
+	}
+
+	config, err := bootstrap.UseTLS(api.V)
+	if err != nil {
+		panic(err)
+	}
+	server := &http.Server{
+		Addr:              api.V.Address,
+		Handler:           otelhttp.NewHandler(api.Metrics(api.RequestId(api.Recover(api.Cors(api.Limit(api.Compress(http.DefaultServeMux)))))), "fn"),
+		TLSConfig:         config,
+		ReadTimeout:       api.V.Server.ReadTimeout,
+		ReadHeaderTimeout: api.V.Server.ReadHeaderTimeout,
+		MaxHeaderBytes:    api.V.Server.MaxHeaderSize,
+	}
+	if config != nil {
+		err = server.ListenAndServeTLS("", "")
+	} else {
+		err = server.ListenAndServe()
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		panic(err)
+	}
 }